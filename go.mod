@@ -6,6 +6,7 @@ require (
 	github.com/restinthemiddle/core v0.0.0-20220104234310-3a983e97c33a
 	github.com/restinthemiddle/logwriter v0.0.0-20220428214508-d6d65854d82f
 	github.com/spf13/viper v1.19.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -27,5 +28,4 @@ require (
 	golang.org/x/sys v0.25.0 // indirect
 	golang.org/x/text v0.18.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// setupLogging configures the standard logger, which is shared by core and logwriter.
+// With dryRun the configuration is only validated and no syslog connection is opened.
+func setupLogging(app appConfig, dryRun bool) error {
+	prefix, err := logPrefix(app.LogStaticFields)
+	if err != nil {
+		return err
+	}
+
+	log.SetFlags(log.LstdFlags | log.Lmsgprefix)
+	log.SetPrefix(prefix)
+
+	var out io.Writer = os.Stderr
+	switch app.LogSink {
+	case "stderr":
+	case "syslog":
+		if err := checkSyslogFacility(app.SyslogFacility); err != nil {
+			return fmt.Errorf("unable to set up syslog, %v", err)
+		}
+		if dryRun {
+			break
+		}
+
+		w, err := newSyslogWriter(app.SyslogFacility, app.SyslogTag)
+		if err != nil {
+			return fmt.Errorf("unable to set up syslog, %v", err)
+		}
+		out = w
+		// syslog adds its own timestamp
+		log.SetFlags(log.Lmsgprefix)
+	default:
+		return fmt.Errorf("unknown logSink %q, use stderr or syslog", app.LogSink)
+	}
+
+	if prefix != "" {
+		out = &prefixWriter{prefix: prefix, out: out}
+	}
+	log.SetOutput(out)
+
+	return nil
+}
+
+// logPrefix renders static fields as sorted key=value pairs for tagging every log line.
+func logPrefix(fields map[string]string) (string, error) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k == "" || needsQuoting(k) {
+			return "", fmt.Errorf("invalid logStaticFields key %q", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	prefix := ""
+	for _, k := range keys {
+		v := fields[k]
+		if needsQuoting(v) {
+			v = strconv.Quote(v)
+		}
+		prefix += fmt.Sprintf("%s=%s ", k, v)
+	}
+
+	return prefix, nil
+}
+
+// needsQuoting reports whether v would break the key=value format if written unquoted.
+func needsQuoting(v string) bool {
+	if strconv.Quote(v) != "\""+v+"\"" || strings.Contains(v, "=") {
+		return true
+	}
+
+	return strings.IndexFunc(v, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0
+}
+
+// prefixWriter repeats the log prefix on every continuation line of a multi-line log entry.
+// The standard logger writes each entry with a single Write call and already prefixes its first line.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	entry := bytes.TrimSuffix(p, []byte("\n"))
+	tagged := bytes.ReplaceAll(entry, []byte("\n"), []byte("\n"+w.prefix))
+	if len(entry) < len(p) {
+		tagged = append(tagged, '\n')
+	}
+
+	if _, err := w.out.Write(tagged); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNeedsQuoting(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "api", want: false},
+		{value: "eu-west-1", want: false},
+		{value: "", want: false},
+		{value: "staging west", want: true},
+		{value: "a\tb", want: true},
+		{value: "a\nb", want: true},
+		{value: "a\"b", want: true},
+		{value: "a=b", want: true},
+		{value: "a b", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := needsQuoting(tt.value); got != tt.want {
+			t.Errorf("needsQuoting(%q): expected %v, got %v", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestLogPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", fields: nil, want: ""},
+		{name: "sorted", fields: map[string]string{"service": "api", "env": "prod"}, want: "env=prod service=api "},
+		{name: "space", fields: map[string]string{"env": "staging west"}, want: `env="staging west" `},
+		{name: "tab", fields: map[string]string{"env": "a\tb"}, want: `env="a\tb" `},
+		{name: "newline", fields: map[string]string{"env": "a\nb"}, want: `env="a\nb" `},
+		{name: "equals", fields: map[string]string{"env": "a=b"}, want: `env="a=b" `},
+		{name: "key with space", fields: map[string]string{"my env": "prod"}, wantErr: true},
+		{name: "key with equals", fields: map[string]string{"env=x": "prod"}, wantErr: true},
+		{name: "empty key", fields: map[string]string{"": "prod"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := logPrefix(tt.fields)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPrefixWriterTagsEveryLine(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&prefixWriter{prefix: "env=prod ", out: &buf}, "env=prod ", log.Lmsgprefix)
+
+	logger.Printf("RESPONSE - Code: 200\nContent-Type: [text/plain]\nContent: hello\n")
+	logger.Print("single line")
+
+	want := "env=prod RESPONSE - Code: 200\nenv=prod Content-Type: [text/plain]\nenv=prod Content: hello\nenv=prod single line\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+// resetLogger restores the standard logger after a test reconfigured it.
+func resetLogger(t *testing.T) {
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetPrefix("")
+		log.SetFlags(log.LstdFlags)
+	})
+}
+
+func TestSetupLoggingRejectsInvalidKey(t *testing.T) {
+	resetLogger(t)
+
+	app := appConfig{LogStaticFields: map[string]string{"my env": "prod"}, LogSink: "stderr"}
+	if err := setupLogging(app, false); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
+
+func TestSetupLoggingRejectsUnknownSink(t *testing.T) {
+	resetLogger(t)
+
+	for _, dryRun := range []bool{false, true} {
+		err := setupLogging(appConfig{LogSink: "file"}, dryRun)
+		if err == nil || !strings.Contains(err.Error(), `unknown logSink "file"`) {
+			t.Errorf("dryRun %v: expected unknown logSink error, got %v", dryRun, err)
+		}
+	}
+}
+
+func TestSetupLoggingRejectsUnknownSyslogFacility(t *testing.T) {
+	resetLogger(t)
+
+	for _, dryRun := range []bool{false, true} {
+		app := appConfig{LogSink: "syslog", SyslogFacility: "bogus", SyslogTag: "restinthemiddle"}
+		if err := setupLogging(app, dryRun); err == nil {
+			t.Errorf("dryRun %v: expected an error for an unknown facility", dryRun)
+		}
+	}
+}
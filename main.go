@@ -1,36 +1,48 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
-	"strings"
+	"syscall"
 
 	"github.com/restinthemiddle/core"
 	"github.com/restinthemiddle/logwriter"
 	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v3"
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print the resolved configuration and exit")
+	generateConfig := flag.Bool("generate-config", false, "print a commented sample configuration file and exit")
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		fmt.Fprintf(flag.CommandLine.Output(), "unexpected argument %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		panic(err)
 	}
 
-	viper.SetDefault("targetHostDsn", "http://host.docker.internal:8081")
-	viper.SetDefault("listenIp", "0.0.0.0")
-	viper.SetDefault("listenPort", "8000")
-	viper.SetDefault("headers", map[string]string{"User-Agent": "Rest in the middle logging proxy"})
-	viper.SetDefault("loggingEnabled", true)
-	viper.SetDefault("setRequestId", false)
-	viper.SetDefault("exclude", "")
+	setDefaults()
 
-	viper.BindEnv("targetHostDsn", "TARGET_HOST_DSN")
-	viper.BindEnv("listenIp", "LISTEN_IP")
-	viper.BindEnv("listenPort", "LISTEN_PORT", "PORT")
-	viper.BindEnv("loggingEnabled", "LOGGING_ENABLED")
-	viper.BindEnv("setRequestId", "SET_REQUEST_ID")
-	viper.BindEnv("exclude", "EXCLUDE")
+	if *generateConfig {
+		if err := writeSampleConfig(os.Stdout); err != nil {
+			log.Panicf("unable to generate config, %v", err)
+		}
+		return
+	}
+
+	bindEnv()
 
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -46,17 +58,44 @@ func main() {
 		}
 	}
 
+	app := appConfig{}
+
+	if err := viper.Unmarshal(&app); err != nil {
+		log.Panicf("unable to decode into struct, %v", err)
+	}
+
+	if err := setupLogging(app, *printConfig); err != nil {
+		log.Fatal(err)
+	}
+
+	warnDeprecatedEnv()
+
 	config := core.Config{}
 
 	if err := viper.Unmarshal(&config); err != nil {
 		log.Panicf("unable to decode into struct, %v", err)
 	}
 
-	headersProcessed := map[string]string{"User-Agent": "Rest in the middle logging proxy"}
-	for k, v := range config.Headers {
-		headersProcessed[strings.Title(strings.ToLower(k))] = v
+	if config.TargetHostDsn == "-" {
+		targetHostDsn, err := readTargetHostDsn(os.Stdin)
+		if err != nil {
+			log.Fatalf("unable to read targetHostDsn from stdin, %v", err)
+		}
+		config.TargetHostDsn = targetHostDsn
+	}
+
+	targetHostDsn, err := normalizeTargetHostDsn(config.TargetHostDsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.TargetHostDsn = targetHostDsn
+
+	config.Headers = processHeaders(config.Headers)
+
+	if *printConfig {
+		printResolvedConfig(config, app)
+		return
 	}
-	config.Headers = headersProcessed
 
 	config.PrintConfig()
 
@@ -65,7 +104,80 @@ func main() {
 		fmt.Printf("Config File: %s\n", configFileUsed)
 	}
 
-	w := logwriter.Writer{}
+	if app.StartupProbe {
+		if err := probeTarget(config.TargetHostDsn, config.Headers); err != nil {
+			if app.StartupProbeFailFast {
+				log.Fatalf("startup probe failed, %v", err)
+			}
+			log.Printf("startup probe failed, %v", err)
+		}
+	}
+
+	w := upstreamWriter{writer: &logwriter.Writer{}}
+
+	if err := checkListenAddress(config.ListenIp, config.ListenPort); err != nil {
+		log.Fatal(err)
+	}
 
 	core.Run(&config, &w)
 }
+
+// printResolvedConfig prints the merged core and app configuration as YAML with credentials redacted.
+func printResolvedConfig(config core.Config, app appConfig) {
+	if u, err := url.Parse(config.TargetHostDsn); err == nil {
+		config.TargetHostDsn = u.Redacted()
+	}
+	config.Headers = redactHeaders(config.Headers)
+
+	root, err := configNode(config, app)
+	if err != nil {
+		log.Panicf("unable to encode config, %v", err)
+	}
+
+	yamlString, err := yaml.Marshal(root)
+	if err != nil {
+		log.Panicf("unable to encode config, %v", err)
+	}
+	fmt.Print(string(yamlString))
+
+	configFileUsed := viper.ConfigFileUsed()
+	if len(configFileUsed) > 0 {
+		fmt.Printf("# Config File: %s\n", configFileUsed)
+	}
+}
+
+// sensitiveHeaders lists the headers whose values are masked when printing the config.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// redactHeaders returns a copy of headers with the values of sensitive headers masked.
+func redactHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for k, v := range headers {
+		redacted[k] = v
+		for _, sensitive := range sensitiveHeaders {
+			if http.CanonicalHeaderKey(k) == sensitive {
+				redacted[k] = "xxxxx"
+			}
+		}
+	}
+
+	return redacted
+}
+
+// wsaeaddrinuse is the Windows counterpart of EADDRINUSE, which syscall does not map.
+const wsaeaddrinuse = syscall.Errno(10048)
+
+// checkListenAddress fails fast with a descriptive error if the listen address cannot be bound.
+// It must run right before core.Run, as the address is only probed, not held.
+func checkListenAddress(ip, port string) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%s", ip, port))
+	if err != nil {
+		if errors.Is(err, syscall.EADDRINUSE) || errors.Is(err, wsaeaddrinuse) {
+			return fmt.Errorf("port %s is already in use on %s, choose another one via listenPort or LISTEN_PORT", port, ip)
+		}
+
+		return fmt.Errorf("unable to listen on %s:%s, %v", ip, port, err)
+	}
+
+	return listener.Close()
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	headers := map[string]string{
+		"Authorization":       "Bearer secret",
+		"proxy-authorization": "Basic c2VjcmV0",
+		"Cookie":              "session=secret",
+		"X-App-Version":       "3.0.0",
+	}
+
+	got := redactHeaders(headers)
+
+	want := map[string]string{
+		"Authorization":       "xxxxx",
+		"proxy-authorization": "xxxxx",
+		"Cookie":              "xxxxx",
+		"X-App-Version":       "3.0.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if headers["Authorization"] != "Bearer secret" {
+		t.Error("expected the original headers to be left untouched")
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// probeTarget issues a HEAD request against the target host DSN and logs the outcome.
+func probeTarget(targetHostDsn string, headers map[string]string) error {
+	targetURL, err := url.Parse(targetHostDsn)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodHead, targetURL.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	start := time.Now()
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	log.Printf("startup probe: HEAD %s returned %s in %s", targetURL.Redacted(), response.Status, time.Since(start))
+
+	return nil
+}
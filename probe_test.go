@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestProbeTargetSendsHeaders(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	var method, appVersion string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		appVersion = r.Header.Get("X-App-Version")
+	}))
+	defer upstream.Close()
+
+	if err := probeTarget(upstream.URL, map[string]string{"X-App-Version": "3.0.0"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if method != http.MethodHead {
+		t.Errorf("expected a HEAD request, got %s", method)
+	}
+	if appVersion != "3.0.0" {
+		t.Errorf("expected X-App-Version header %q, got %q", "3.0.0", appVersion)
+	}
+}
+
+func TestProbeTargetFailsForUnreachableTarget(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstream.Close()
+
+	if err := probeTarget(upstream.URL, nil); err == nil {
+		t.Error("expected an error for an unreachable target")
+	}
+}
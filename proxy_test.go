@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/restinthemiddle/core"
+	"github.com/restinthemiddle/logwriter"
+)
+
+var (
+	proxyOnce sync.Once
+	proxyURL  string
+	proxyErr  error
+)
+
+// startProxy runs core.Run once per test binary against an httptest upstream.
+// core registers its handler on http.DefaultServeMux and can therefore only be
+// started once, so all end-to-end tests share this instance and its configuration.
+func startProxy(t *testing.T) string {
+	t.Helper()
+
+	proxyOnce.Do(func() {
+		upstream := httptest.NewServer(http.HandlerFunc(echoUpstream))
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			proxyErr = err
+			return
+		}
+		port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+		listener.Close()
+
+		config := core.Config{
+			TargetHostDsn:  upstream.URL,
+			ListenIp:       "127.0.0.1",
+			ListenPort:     port,
+			Headers:        processHeaders(nil),
+			LoggingEnabled: false,
+			SetRequestId:   false,
+		}
+		w := upstreamWriter{writer: &logwriter.Writer{}}
+		go core.Run(&config, &w)
+
+		for i := 0; i < 200; i++ {
+			if conn, err := net.Dial("tcp", "127.0.0.1:"+port); err == nil {
+				conn.Close()
+				proxyURL = "http://127.0.0.1:" + port
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		proxyErr = errors.New("proxy did not start")
+	})
+
+	if proxyErr != nil {
+		t.Fatal(proxyErr)
+	}
+
+	return proxyURL
+}
+
+// echoUpstream reports what it received from the proxy in its response headers.
+func echoUpstream(w http.ResponseWriter, r *http.Request) {
+	hash := sha256.New()
+	n, err := io.Copy(hash, r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Received-Bytes", strconv.FormatInt(n, 10))
+	w.Header().Set("X-Received-Sha256", hex.EncodeToString(hash.Sum(nil)))
+	if requestId, ok := r.Header["X-Request-Id"]; ok {
+		w.Header().Set("X-Received-Request-Id", strings.Join(requestId, ","))
+	}
+	fmt.Fprint(w, "ok")
+}
+
+func TestProxy(t *testing.T) {
+	proxy := startProxy(t)
+
+	t.Run("streams large upload with logging disabled", func(t *testing.T) {
+		const size = 32 << 20
+
+		sent := sha256.New()
+		body := io.TeeReader(io.LimitReader(rand.New(rand.NewSource(1)), size), sent)
+
+		request, err := http.NewRequest(http.MethodPut, proxy+"/upload", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.ContentLength = size
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		response.Body.Close()
+
+		if got := response.Header.Get("X-Received-Bytes"); got != strconv.Itoa(size) {
+			t.Errorf("expected upstream to receive %d bytes, got %s", size, got)
+		}
+		if got, want := response.Header.Get("X-Received-Sha256"), hex.EncodeToString(sent.Sum(nil)); got != want {
+			t.Errorf("expected upstream body hash %s, got %s", want, got)
+		}
+	})
+	t.Run("passes a client X-Request-Id through unchanged", func(t *testing.T) {
+		const requestId = "client-supplied id"
+
+		request, err := http.NewRequest(http.MethodGet, proxy+"/visitors", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.Header.Set("X-Request-Id", requestId)
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		response.Body.Close()
+
+		if got := response.Header.Get("X-Received-Request-Id"); got != requestId {
+			t.Errorf("expected upstream to receive X-Request-Id %q, got %q", requestId, got)
+		}
+	})
+
+	t.Run("adds no X-Request-Id if the client sent none", func(t *testing.T) {
+		response, err := http.Get(proxy + "/visitors")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		response.Body.Close()
+
+		if got, ok := response.Header["X-Received-Request-Id"]; ok {
+			t.Errorf("expected upstream to receive no X-Request-Id, got %q", got)
+		}
+		if got, ok := response.Header["X-Request-Id"]; ok {
+			t.Errorf("expected no X-Request-Id in the response, got %q", got)
+		}
+	})
+	t.Run("proxies HEAD requests without a body", func(t *testing.T) {
+		response, err := http.Head(proxy + "/visitors")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, response.StatusCode)
+		}
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(body) != 0 {
+			t.Errorf("expected an empty body, got %q", body)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"strings"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// checkSyslogFacility validates the facility name without connecting to syslog.
+func checkSyslogFacility(facility string) error {
+	if _, ok := syslogFacilities[strings.ToLower(facility)]; !ok {
+		return fmt.Errorf("unknown syslog facility %q", facility)
+	}
+
+	return nil
+}
+
+// newSyslogWriter connects to the local syslog daemon using the given facility and tag.
+func newSyslogWriter(facility, tag string) (io.Writer, error) {
+	if err := checkSyslogFacility(facility); err != nil {
+		return nil, err
+	}
+
+	return syslog.New(syslogFacilities[strings.ToLower(facility)]|syslog.LOG_INFO, tag)
+}
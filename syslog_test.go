@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package main
+
+import "testing"
+
+func TestSetupLoggingSyslogDryRunDoesNotConnect(t *testing.T) {
+	resetLogger(t)
+
+	app := appConfig{LogSink: "syslog", SyslogFacility: "local0", SyslogTag: "restinthemiddle"}
+	if err := setupLogging(app, true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
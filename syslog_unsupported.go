@@ -0,0 +1,20 @@
+//go:build windows || plan9
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+var errSyslogUnsupported = errors.New("syslog is not supported on this platform")
+
+// checkSyslogFacility always fails because log/syslog is not available on this platform.
+func checkSyslogFacility(facility string) error {
+	return errSyslogUnsupported
+}
+
+// newSyslogWriter always fails because log/syslog is not available on this platform.
+func newSyslogWriter(facility, tag string) (io.Writer, error) {
+	return nil, errSyslogUnsupported
+}
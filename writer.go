@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/restinthemiddle/core"
+)
+
+// upstreamWriter logs what was actually sent upstream (after the director rewrote the
+// request) and then delegates to the wrapped writer.
+type upstreamWriter struct {
+	writer core.Writer
+}
+
+func (w *upstreamWriter) LogResponse(response *http.Response) (err error) {
+	args := ""
+	if rawQuery := response.Request.URL.RawQuery; len(rawQuery) > 0 {
+		args = fmt.Sprintf("?%s", rawQuery)
+	}
+
+	log.Printf("UPSTREAM - URL: %s; Args: %s; Proto: %s\n", response.Request.URL.Redacted(), args, response.Proto)
+
+	return w.writer.LogResponse(response)
+}
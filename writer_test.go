@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/restinthemiddle/logwriter"
+)
+
+type recordingWriter struct {
+	responses []*http.Response
+}
+
+func (w *recordingWriter) LogResponse(response *http.Response) (err error) {
+	w.responses = append(w.responses, response)
+
+	return nil
+}
+
+func TestUpstreamWriterLogResponse(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	request := httptest.NewRequest(http.MethodGet, "http://upstream.example.com:8081/base/visitors?start=1&page=2", nil)
+	response := &http.Response{StatusCode: http.StatusOK, Proto: "HTTP/1.1", Request: request}
+
+	inner := &recordingWriter{}
+	w := &upstreamWriter{writer: inner}
+	if err := w.LogResponse(response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"UPSTREAM - URL: http://upstream.example.com:8081/base/visitors?start=1&page=2",
+		"; Args: ?start=1&page=2",
+		"; Proto: HTTP/1.1",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected log to contain %q, got %q", want, buf.String())
+		}
+	}
+
+	if len(inner.responses) != 1 || inner.responses[0] != response {
+		t.Error("expected the response to be passed to the wrapped writer")
+	}
+}
+
+func TestUpstreamWriterLogsEmptyBodyForHead(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "2")
+		fmt.Fprint(w, "ok")
+	}))
+	defer upstream.Close()
+
+	response, err := http.Head(upstream.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.ContentLength != 2 {
+		t.Fatalf("expected a declared Content-Length of 2, got %d", response.ContentLength)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	w := &upstreamWriter{writer: &logwriter.Writer{}}
+	if err := w.LogResponse(response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasSuffix(buf.String(), "Content: \n") {
+		t.Errorf("expected an empty logged body, got %q", buf.String())
+	}
+}